@@ -0,0 +1,37 @@
+package grsync
+
+import "regexp"
+
+// matcher is a thin wrapper around a compiled regular expression used to
+// pull individual fields out of rsync's progress output.
+type matcher struct {
+	re *regexp.Regexp
+}
+
+func newMatcher(pattern string) *matcher {
+	return &matcher{
+		re: regexp.MustCompile(pattern),
+	}
+}
+
+// Match reports whether the string contains a match for the matcher's
+// pattern.
+func (m *matcher) Match(str string) bool {
+	return m.re.MatchString(str)
+}
+
+// Extract returns the first submatch of the matcher's pattern, or an empty
+// string if the pattern didn't match.
+func (m *matcher) Extract(str string) string {
+	groups := m.re.FindStringSubmatch(str)
+	if len(groups) < 2 {
+		return ""
+	}
+
+	return groups[1]
+}
+
+// ExtractAllStringSubmatch returns up to n matches of the matcher's pattern.
+func (m *matcher) ExtractAllStringSubmatch(str string, n int) [][]string {
+	return m.re.FindAllStringSubmatch(str, n)
+}
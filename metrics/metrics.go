@@ -0,0 +1,144 @@
+// Package metrics exposes Prometheus collectors that report the live
+// progress of a grsync.Task by consuming its channel-based event stream.
+package metrics
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/signcl/grsync"
+)
+
+const namespace = "grsync"
+
+// Collector is a prometheus.Collector reporting live progress for a single
+// Task. It unregisters itself once the task's event stream ends, so
+// long-running operators don't accumulate series for completed tasks.
+type Collector struct {
+	progress    prometheus.Gauge
+	remain      prometheus.Gauge
+	total       prometheus.Gauge
+	transferred prometheus.Gauge
+	completed   *prometheus.CounterVec
+	errors      prometheus.Counter
+
+	mu     sync.Mutex
+	closed bool
+}
+
+// Register creates a Collector for task, seeds it with the task's current
+// State, and registers it with the default Prometheus registerer. It
+// returns the collector along with an events channel; forward task's
+// events there (e.g. by passing it as the events argument to
+// task.RunContext) to keep the metrics live. The collector unregisters
+// itself as soon as events is closed or a terminal StateEvent (EventDone or
+// EventError) arrives.
+func Register(task *grsync.Task, labels prometheus.Labels) (*Collector, chan<- grsync.StateEvent, error) {
+	c := &Collector{
+		progress: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace:   namespace,
+			Name:        "task_progress",
+			Help:        "Current transfer progress of the task, from 0 to 100.",
+			ConstLabels: labels,
+		}),
+		remain: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace:   namespace,
+			Name:        "task_remain_files",
+			Help:        "Number of files remaining to be transferred.",
+			ConstLabels: labels,
+		}),
+		total: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace:   namespace,
+			Name:        "task_total_files",
+			Help:        "Total number of files considered for transfer.",
+			ConstLabels: labels,
+		}),
+		transferred: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace:   namespace,
+			Name:        "task_transferred_bytes",
+			Help:        "Bytes transferred so far.",
+			ConstLabels: labels,
+		}),
+		completed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Name:        "task_completed_total",
+			Help:        "Number of times the task finished, by status.",
+			ConstLabels: labels,
+		}, []string{"status"}),
+		errors: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Name:        "task_errors_total",
+			Help:        "Number of errors observed on the task's event stream.",
+			ConstLabels: labels,
+		}),
+	}
+	c.update(task.State())
+
+	if err := prometheus.Register(c); err != nil {
+		return nil, nil, err
+	}
+
+	events := make(chan grsync.StateEvent)
+	go c.consume(events)
+
+	return c, events, nil
+}
+
+func (c *Collector) consume(events chan grsync.StateEvent) {
+	for event := range events {
+		c.update(event.State)
+
+		switch event.Kind {
+		case grsync.EventDone:
+			c.completed.WithLabelValues("done").Inc()
+			c.unregister()
+			return
+		case grsync.EventError:
+			c.errors.Inc()
+			c.completed.WithLabelValues("error").Inc()
+			c.unregister()
+			return
+		}
+	}
+
+	c.unregister()
+}
+
+func (c *Collector) update(state grsync.State) {
+	c.progress.Set(state.Progress)
+	c.remain.Set(float64(state.Remain))
+	c.total.Set(float64(state.Total))
+	c.transferred.Set(float64(state.TransferedBytes))
+}
+
+func (c *Collector) unregister() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return
+	}
+	c.closed = true
+	prometheus.Unregister(c)
+}
+
+// Describe implements prometheus.Collector.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	c.progress.Describe(ch)
+	c.remain.Describe(ch)
+	c.total.Describe(ch)
+	c.transferred.Describe(ch)
+	c.completed.Describe(ch)
+	c.errors.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.progress.Collect(ch)
+	c.remain.Collect(ch)
+	c.total.Collect(ch)
+	c.transferred.Collect(ch)
+	c.completed.Collect(ch)
+	c.errors.Collect(ch)
+}
@@ -0,0 +1,80 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/signcl/grsync"
+)
+
+func TestCollectorConsumeEventDoneUnregisters(t *testing.T) {
+	task := grsync.NewTask("a", "b", grsync.RsyncOptions{})
+
+	c, events, err := Register(task, prometheus.Labels{"name": "done"})
+	require.NoError(t, err)
+
+	events <- grsync.StateEvent{
+		State: grsync.State{Progress: 42, Remain: 1, Total: 2},
+		Kind:  grsync.EventDone,
+	}
+	assertEventuallyClosed(t, c)
+	assert.Equal(t, float64(42), testutil.ToFloat64(c.progress))
+
+	// A second Register under the same labels only succeeds if the first
+	// Collector actually unregistered itself from the default registerer.
+	c2, _, err := Register(task, prometheus.Labels{"name": "done"})
+	require.NoError(t, err)
+	c2.unregister()
+}
+
+func TestCollectorConsumeEventErrorUnregisters(t *testing.T) {
+	task := grsync.NewTask("a", "b", grsync.RsyncOptions{})
+
+	c, events, err := Register(task, prometheus.Labels{"name": "error"})
+	require.NoError(t, err)
+
+	events <- grsync.StateEvent{
+		State: grsync.State{Progress: 10},
+		Kind:  grsync.EventError,
+	}
+	assertEventuallyClosed(t, c)
+	assert.Equal(t, float64(1), testutil.ToFloat64(c.errors))
+}
+
+func TestCollectorConsumeChannelCloseUnregisters(t *testing.T) {
+	task := grsync.NewTask("a", "b", grsync.RsyncOptions{})
+
+	c, events, err := Register(task, prometheus.Labels{"name": "closed"})
+	require.NoError(t, err)
+
+	close(events)
+	assertEventuallyClosed(t, c)
+}
+
+// assertEventuallyClosed waits for c to unregister itself, which happens on
+// its own consume goroutine once a terminal event (or channel close)
+// arrives.
+func assertEventuallyClosed(t *testing.T, c *Collector) {
+	t.Helper()
+
+	require.Eventually(t, func() bool {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return c.closed
+	}, time.Second, time.Millisecond, "collector never unregistered")
+}
+
+func TestCollectorUpdateSeedsGaugesFromState(t *testing.T) {
+	task := grsync.NewTask("a", "b", grsync.RsyncOptions{})
+
+	c, _, err := Register(task, prometheus.Labels{"name": "seed"})
+	require.NoError(t, err)
+	defer c.unregister()
+
+	assert.Equal(t, float64(0), testutil.ToFloat64(c.progress))
+}
@@ -0,0 +1,117 @@
+package grsync
+
+import "strconv"
+
+// RsyncOptions describes a set of options for the rsync command. Fields left
+// at their zero value do not add a flag to the generated command line.
+type RsyncOptions struct {
+	Verbose       bool
+	Quiet         bool
+	Archive       bool
+	Recursive     bool
+	Relative      bool
+	Update        bool
+	Links         bool
+	Perms         bool
+	Owner         bool
+	Group         bool
+	Devices       bool
+	Times         bool
+	HumanReadable bool
+	Progress      bool
+	Partial       bool
+	Delete        bool
+	DryRun        bool
+	Checksum      bool
+
+	// Progress2 requests rsync's aggregate --info=progress2 output (a
+	// single running total for the whole transfer) instead of its
+	// default per-file progress line.
+	Progress2 bool
+
+	// ItemizeChanges requests rsync's per-file itemize output (the
+	// 11-character YXcstpoguax prefix), which Task parses into FileEvent
+	// values when set.
+	ItemizeChanges bool
+
+	Exclude   []string
+	RsyncPath string
+	Bwlimit   int
+}
+
+// toArgs renders the options as a slice of rsync command-line arguments.
+func (options RsyncOptions) toArgs() []string {
+	var args []string
+
+	if options.Verbose {
+		args = append(args, "--verbose")
+	}
+	if options.Quiet {
+		args = append(args, "--quiet")
+	}
+	if options.Archive {
+		args = append(args, "--archive")
+	}
+	if options.Recursive {
+		args = append(args, "--recursive")
+	}
+	if options.Relative {
+		args = append(args, "--relative")
+	}
+	if options.Update {
+		args = append(args, "--update")
+	}
+	if options.Links {
+		args = append(args, "--links")
+	}
+	if options.Perms {
+		args = append(args, "--perms")
+	}
+	if options.Owner {
+		args = append(args, "--owner")
+	}
+	if options.Group {
+		args = append(args, "--group")
+	}
+	if options.Devices {
+		args = append(args, "--devices")
+	}
+	if options.Times {
+		args = append(args, "--times")
+	}
+	if options.HumanReadable {
+		args = append(args, "--human-readable")
+	}
+	if options.Progress {
+		args = append(args, "--progress")
+	}
+	if options.Progress2 {
+		args = append(args, "--info=progress2")
+	}
+	if options.Partial {
+		args = append(args, "--partial")
+	}
+	if options.Delete {
+		args = append(args, "--delete")
+	}
+	if options.DryRun {
+		args = append(args, "--dry-run")
+	}
+	if options.Checksum {
+		args = append(args, "--checksum")
+	}
+	if options.ItemizeChanges {
+		args = append(args, "--itemize-changes")
+	}
+	if options.RsyncPath != "" {
+		args = append(args, "--rsync-path", options.RsyncPath)
+	}
+	if options.Bwlimit > 0 {
+		args = append(args, "--bwlimit", strconv.Itoa(options.Bwlimit))
+	}
+	for _, exclude := range options.Exclude {
+		args = append(args, "--exclude", exclude)
+	}
+
+	return args
+}
@@ -0,0 +1,160 @@
+package grsync
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// PoolOptions configures a Pool.
+type PoolOptions struct {
+	// Concurrency is the maximum number of rsync processes running at
+	// once. Defaults to 1 if not positive.
+	Concurrency int
+
+	// LaunchDelay is the minimum delay enforced between successive rsync
+	// process starts, regardless of Concurrency. This keeps bursts of
+	// parallel rsync-over-SSH connections from tripping fail2ban or
+	// sshd's MaxStartups.
+	LaunchDelay time.Duration
+
+	// StopOnError stops launching new tasks once one of them returns an
+	// error. Tasks already running are allowed to finish.
+	StopOnError bool
+}
+
+// TaskResult is the outcome of running a single Task through a Pool.
+type TaskResult struct {
+	Task  *Task
+	State State
+	Log   Log
+	Err   error
+}
+
+// Pool runs a set of Tasks with a bounded worker count and an optional
+// delay between successive rsync process launches.
+type Pool struct {
+	options PoolOptions
+
+	mu    sync.Mutex
+	tasks []*Task
+}
+
+// NewPool returns a new Pool configured with options.
+func NewPool(options PoolOptions) *Pool {
+	if options.Concurrency <= 0 {
+		options.Concurrency = 1
+	}
+
+	return &Pool{options: options}
+}
+
+// Add queues a Task to be run by the pool.
+func (p *Pool) Add(task *Task) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.tasks = append(p.tasks, task)
+}
+
+// State returns the aggregate remain/total file counts across all tasks
+// added to the pool, whether queued, running or finished, suitable for
+// whole-batch progress reporting.
+func (p *Pool) State() State {
+	p.mu.Lock()
+	tasks := append([]*Task(nil), p.tasks...)
+	p.mu.Unlock()
+
+	var state State
+	for _, task := range tasks {
+		s := task.State()
+		state.Remain += s.Remain
+		state.Total += s.Total
+		state.TransferedBytes += s.TransferedBytes
+	}
+
+	if state.Total > 0 {
+		copied := float64(state.Total - state.Remain)
+		state.Progress = copied / float64(state.Total) * 100
+	}
+
+	return state
+}
+
+// Run launches the pool's tasks, honoring Concurrency and LaunchDelay, and
+// returns a channel of TaskResult - one per task, delivered as each task
+// finishes. The channel is closed once every task has finished or ctx is
+// done. If StopOnError is set, no further tasks are launched after the
+// first error, though already-running tasks are allowed to finish.
+func (p *Pool) Run(ctx context.Context) (<-chan TaskResult, error) {
+	p.mu.Lock()
+	tasks := append([]*Task(nil), p.tasks...)
+	p.mu.Unlock()
+
+	results := make(chan TaskResult, len(tasks))
+
+	go func() {
+		defer close(results)
+
+		sem := make(chan struct{}, p.options.Concurrency)
+		stop := make(chan struct{})
+		var stopOnce sync.Once
+		var wg sync.WaitGroup
+
+		for i, task := range tasks {
+			if i > 0 && p.options.LaunchDelay > 0 {
+				select {
+				case <-time.After(p.options.LaunchDelay):
+				case <-ctx.Done():
+					wg.Wait()
+					return
+				}
+			}
+
+			select {
+			case <-stop:
+				wg.Wait()
+				return
+			case <-ctx.Done():
+				wg.Wait()
+				return
+			default:
+			}
+
+			select {
+			case sem <- struct{}{}:
+			case <-stop:
+				wg.Wait()
+				return
+			case <-ctx.Done():
+				wg.Wait()
+				return
+			}
+
+			select {
+			case <-stop:
+				<-sem
+				wg.Wait()
+				return
+			default:
+			}
+
+			wg.Add(1)
+			go func(task *Task) {
+				defer wg.Done()
+				defer func() { <-sem }()
+
+				err := task.RunContext(ctx, nil)
+				results <- TaskResult{Task: task, State: task.State(), Log: task.Log(), Err: err}
+
+				if err != nil && p.options.StopOnError {
+					stopOnce.Do(func() { close(stop) })
+				}
+			}(task)
+		}
+
+		wg.Wait()
+	}()
+
+	return results, nil
+}
@@ -0,0 +1,122 @@
+package grsync
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withFakeRsync puts a fake rsync binary on PATH for the duration of t, so
+// Pool tests can exercise real Task/Rsync plumbing without depending on rsync
+// being installed. The fake reads its last two arguments as source and
+// destination: it touches source as a per-task marker, sleeps for the
+// duration destination names (or not at all if destination is "ok"), and
+// exits non-zero when destination is "fail".
+func withFakeRsync(t *testing.T) {
+	t.Helper()
+
+	dir := t.TempDir()
+	script := "#!/bin/sh\n" +
+		"eval src=\\${$(($#-1))}\n" +
+		"eval dst=\\${$#}\n" +
+		"touch \"$src\"\n" +
+		"case \"$dst\" in\n" +
+		"  fail) echo 'rsync: fake failure' >&2; exit 1 ;;\n" +
+		"  sleep:*) sleep \"${dst#sleep:}\" ;;\n" +
+		"esac\n" +
+		"exit 0\n"
+
+	path := filepath.Join(dir, "rsync")
+	require.NoError(t, os.WriteFile(path, []byte(script), 0o755))
+
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestPoolConcurrencyRespected(t *testing.T) {
+	withFakeRsync(t)
+
+	const tasks = 4
+	const concurrency = 2
+	const sleep = 100 * time.Millisecond
+
+	pool := NewPool(PoolOptions{Concurrency: concurrency})
+	for i := 0; i < tasks; i++ {
+		marker := filepath.Join(t.TempDir(), "marker")
+		dest := fmt.Sprintf("sleep:%.2f", sleep.Seconds())
+		pool.Add(NewTask(marker, dest, RsyncOptions{}))
+	}
+
+	start := time.Now()
+	results, err := pool.Run(context.Background())
+	require.NoError(t, err)
+
+	// Poll State concurrently with the tasks' own event-processing
+	// goroutines, the pattern Pool.State's doc comment describes. Run
+	// under `go test -race` to catch unsynchronized access to Task.state.
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				pool.State()
+				time.Sleep(time.Millisecond)
+			}
+		}
+	}()
+
+	var n int
+	for range results {
+		n++
+	}
+	elapsed := time.Since(start)
+	close(stop)
+	<-stopped
+
+	assert.Equal(t, tasks, n)
+	// Fully serial would take tasks*sleep; bounding Concurrency to 2 should
+	// land around two batches, well under running all four in sequence.
+	assert.Less(t, elapsed, time.Duration(tasks)*sleep)
+}
+
+func TestPoolStopOnError(t *testing.T) {
+	withFakeRsync(t)
+
+	const tasks = 4
+
+	markers := make([]string, tasks)
+	pool := NewPool(PoolOptions{Concurrency: 1, StopOnError: true})
+	for i := range markers {
+		markers[i] = filepath.Join(t.TempDir(), "marker")
+
+		dest := "ok"
+		if i == 0 {
+			dest = "fail"
+		}
+		pool.Add(NewTask(markers[i], dest, RsyncOptions{}))
+	}
+
+	results, err := pool.Run(context.Background())
+	require.NoError(t, err)
+
+	var errCount int
+	for result := range results {
+		if result.Err != nil {
+			errCount++
+		}
+	}
+
+	assert.Equal(t, 1, errCount)
+	assert.FileExists(t, markers[0])
+	assert.NoFileExists(t, markers[1], "StopOnError should keep the very next task from launching")
+	assert.NoFileExists(t, markers[len(markers)-1], "StopOnError should keep later tasks from ever launching")
+}
@@ -0,0 +1,142 @@
+// Package render draws a Task's progress to a terminal as a single-line,
+// carriage-return-updated status bar, in the style of lxd's
+// ProgressRenderer and butler's comm.Progress.
+package render
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/signcl/grsync"
+)
+
+// IEC binary byte units used by formatBytes.
+const (
+	kib = 1 << 10
+	mib = 1 << 20
+	gib = 1 << 30
+	tib = 1 << 40
+)
+
+// Renderer draws a running Task's progress to a terminal.
+type Renderer struct {
+	w     io.Writer
+	start time.Time
+
+	events chan grsync.StateEvent
+	done   chan struct{}
+
+	lastWidth int
+}
+
+// NewTerminal returns a Renderer that draws task's progress to w. Pass
+// Events() as the events argument to task.RunContext to feed it. Call Stop
+// once the task is done; RunContext never closes events, so without Stop
+// the Renderer's background goroutine would run for the life of the
+// process.
+func NewTerminal(w io.Writer, task *grsync.Task) *Renderer {
+	r := &Renderer{
+		w:      w,
+		start:  time.Now(),
+		events: make(chan grsync.StateEvent),
+		done:   make(chan struct{}),
+	}
+
+	r.draw(task.State())
+	go r.consume()
+
+	return r
+}
+
+// Events returns the channel the Renderer reads StateEvents from.
+func (r *Renderer) Events() chan<- grsync.StateEvent {
+	return r.events
+}
+
+// Stop terminates the Renderer's background consume goroutine. It is safe
+// to call once, typically right before Done.
+func (r *Renderer) Stop() {
+	close(r.done)
+}
+
+func (r *Renderer) consume() {
+	for {
+		select {
+		case event := <-r.events:
+			r.draw(event.State)
+		case <-r.done:
+			return
+		}
+	}
+}
+
+func (r *Renderer) draw(state grsync.State) {
+	elapsed := state.ElapsedSinceStart
+	if elapsed == 0 {
+		elapsed = time.Since(r.start)
+	}
+
+	line := fmt.Sprintf("[%s] %5.1f%% %s/%s  %s  ETA %s  %s",
+		formatDuration(elapsed),
+		state.Progress,
+		formatBytes(state.TransferredTotalBytes),
+		formatBytes(state.TotalBytes),
+		state.Speed,
+		formatDuration(state.ETA),
+		state.Filename,
+	)
+
+	r.write(line)
+}
+
+// Done clears the status line and prints a final summary.
+func (r *Renderer) Done(summary string) {
+	r.write("")
+	fmt.Fprintf(r.w, "\r%s\n", summary)
+}
+
+// write redraws the status line, padding with spaces to clear anything left
+// over from a longer previous line. It starts with a carriage return, so
+// each redraw overwrites the previous one in place.
+func (r *Renderer) write(line string) {
+	pad := ""
+	if r.lastWidth > len(line) {
+		pad = strings.Repeat(" ", r.lastWidth-len(line))
+	}
+	r.lastWidth = len(line)
+
+	fmt.Fprintf(r.w, "\r%s%s", line, pad)
+}
+
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+
+	h := d / time.Hour
+	d -= h * time.Hour
+	m := d / time.Minute
+	d -= m * time.Minute
+	s := d / time.Second
+
+	return fmt.Sprintf("%d:%02d:%02d", h, m, s)
+}
+
+// formatBytes renders n using IEC binary units (KiB/MiB/GiB/TiB) instead of
+// pulling in humanize as a dependency.
+func formatBytes(n int64) string {
+	v := float64(n)
+
+	switch {
+	case v >= tib:
+		return fmt.Sprintf("%.1f TiB", v/tib)
+	case v >= gib:
+		return fmt.Sprintf("%.1f GiB", v/gib)
+	case v >= mib:
+		return fmt.Sprintf("%.1f MiB", v/mib)
+	case v >= kib:
+		return fmt.Sprintf("%.1f KiB", v/kib)
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
@@ -0,0 +1,88 @@
+package render
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/signcl/grsync"
+)
+
+// syncBuffer lets a test read a Renderer's output while its consume
+// goroutine is concurrently writing to it.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) Contains(sub string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return bytes.Contains(b.buf.Bytes(), []byte(sub))
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		bytes    int64
+		expected string
+	}{
+		{512, "512 B"},
+		{2048, "2.0 KiB"},
+		{5 * 1024 * 1024, "5.0 MiB"},
+		{3 * 1024 * 1024 * 1024, "3.0 GiB"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, formatBytes(tt.bytes))
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	assert.Equal(t, "0:00:18", formatDuration(18*time.Second))
+	assert.Equal(t, "1:02:03", formatDuration(time.Hour+2*time.Minute+3*time.Second))
+}
+
+func TestNewTerminalConsumesEvents(t *testing.T) {
+	task := grsync.NewTask("a", "b", grsync.RsyncOptions{})
+
+	buf := &syncBuffer{}
+	r := NewTerminal(buf, task)
+	defer r.Stop()
+
+	r.Events() <- grsync.StateEvent{
+		State: grsync.State{Progress: 50, Filename: "file.txt"},
+		Kind:  grsync.EventProgress,
+	}
+
+	require.Eventually(t, func() bool {
+		return buf.Contains("file.txt")
+	}, time.Second, time.Millisecond, "consume never drew the pushed event")
+}
+
+func TestRendererStopEndsConsume(t *testing.T) {
+	task := grsync.NewTask("a", "b", grsync.RsyncOptions{})
+
+	r := NewTerminal(&syncBuffer{}, task)
+	r.Stop()
+
+	// Give consume's goroutine a moment to observe r.done and return.
+	// RunContext never closes Events(), so without Stop this goroutine
+	// (and the unread send below) would leak for the life of the process.
+	time.Sleep(20 * time.Millisecond)
+
+	select {
+	case r.Events() <- grsync.StateEvent{}:
+		t.Fatal("consume still reading events after Stop")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
@@ -0,0 +1,67 @@
+package grsync
+
+import (
+	"io"
+	"os/exec"
+)
+
+// Rsync wraps an rsync invocation built from a source, a destination and a
+// set of RsyncOptions.
+type Rsync struct {
+	cmd *exec.Cmd
+}
+
+// NewRsync returns a new Rsync ready to be run against source and
+// destination with the given options.
+func NewRsync(source, destination string, options RsyncOptions) *Rsync {
+	arguments := options.toArgs()
+	arguments = append(arguments, source, destination)
+
+	return &Rsync{
+		cmd: exec.Command("rsync", arguments...),
+	}
+}
+
+// StdoutPipe returns a pipe connected to the command's standard output.
+func (r *Rsync) StdoutPipe() (io.ReadCloser, error) {
+	return r.cmd.StdoutPipe()
+}
+
+// StderrPipe returns a pipe connected to the command's standard error.
+func (r *Rsync) StderrPipe() (io.ReadCloser, error) {
+	return r.cmd.StderrPipe()
+}
+
+// Run starts the rsync command and waits for it to complete.
+func (r *Rsync) Run() error {
+	return r.cmd.Run()
+}
+
+// Start starts the rsync command without waiting for it to complete.
+func (r *Rsync) Start() error {
+	return r.cmd.Start()
+}
+
+// Wait waits for a command started with Start to complete.
+func (r *Rsync) Wait() error {
+	return r.cmd.Wait()
+}
+
+// Kill terminates the underlying rsync process. It is a no-op if the
+// process hasn't been started yet.
+func (r *Rsync) Kill() error {
+	if r.cmd.Process == nil {
+		return nil
+	}
+	return r.cmd.Process.Kill()
+}
+
+// hasArg reports whether arg was passed on the rsync command line.
+func (r *Rsync) hasArg(arg string) bool {
+	for _, a := range r.cmd.Args {
+		if a == arg {
+			return true
+		}
+	}
+	return false
+}
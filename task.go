@@ -3,19 +3,76 @@ package grsync
 import (
 	"bufio"
 	"bytes"
+	"context"
+	"encoding/json"
 	"io"
 	"math"
+	"regexp"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 )
 
+// maxRecentFiles bounds the ring buffer returned by Task.RecentFiles.
+const maxRecentFiles = 100
+
+// fileEventBufferSize is the buffer depth of a Task's Files channel. Sends
+// are non-blocking past this depth so a slow or absent consumer never
+// stalls the underlying rsync process.
+const fileEventBufferSize = 64
+
 // Task is high-level API under rsync
 type Task struct {
 	rsync *Rsync
 
-	state *State
-	log   *Log
+	state   *State
+	stateMu sync.Mutex
+	log     *Log
+	logMu   sync.Mutex
+
+	// JSONProgress, when set, receives one JSON object per state update
+	// plus a terminal summary object once the task completes, so callers
+	// can consume progress without re-parsing rsync's human output.
+	JSONProgress io.Writer
+
+	files       chan FileEvent
+	filesMu     sync.Mutex
+	recentFiles []FileEvent
+}
+
+// FileOp describes the kind of change rsync itemized for a file.
+type FileOp int
+
+// File operations reported in a FileEvent.
+const (
+	OpChanged FileOp = iota
+	OpSent
+	OpReceived
+	OpCreated
+	OpDeleted
+)
+
+// FileKind describes the type of filesystem entry a FileEvent refers to.
+type FileKind int
+
+// File kinds reported in a FileEvent.
+const (
+	KindUnknown FileKind = iota
+	KindFile
+	KindDir
+	KindSymlink
+	KindDevice
+	KindSpecial
+)
+
+// FileEvent is a single itemized change reported by rsync when
+// RsyncOptions.ItemizeChanges is set.
+type FileEvent struct {
+	Op         FileOp
+	Kind       FileKind
+	Path       string
+	LinkTarget string
 }
 
 // State contains information about rsync process
@@ -28,6 +85,13 @@ type State struct {
 
 	TransferedBytes   int64 `json:"transfered_bytes"`
 	TransferedPercent int   `json:"transfered_percent"` // 0 ~ 100
+
+	// The following fields are only populated when RsyncOptions.Progress2
+	// is set, i.e. rsync was run with --info=progress2.
+	TotalBytes            int64         `json:"total_bytes"`
+	TransferredTotalBytes int64         `json:"transferred_total_bytes"`
+	ETA                   time.Duration `json:"eta"`
+	ElapsedSinceStart     time.Duration `json:"elapsed_since_start"`
 }
 
 // Log contains raw stderr and stdout outputs
@@ -36,13 +100,41 @@ type Log struct {
 	Stdout string `json:"stdout"`
 }
 
-// State returns inforation about rsync processing task
-func (t Task) State() State {
+// EventKind identifies the reason a StateEvent was emitted.
+type EventKind int
+
+// Event kinds emitted on a Task's event stream.
+const (
+	EventProgress EventKind = iota
+	EventFile
+	EventError
+	EventDone
+)
+
+// StateEvent is a snapshot of a Task's State at a point in time, pushed to
+// callers of RunContext as the task progresses.
+type StateEvent struct {
+	State State
+	Time  time.Time
+	Kind  EventKind
+}
+
+// State returns inforation about rsync processing task. It is safe to call
+// concurrently with a running task, including from another goroutine such as
+// Pool.State.
+func (t *Task) State() State {
+	t.stateMu.Lock()
+	defer t.stateMu.Unlock()
+
 	return *t.state
 }
 
-// Log return structure which contains raw stderr and stdout outputs
-func (t Task) Log() Log {
+// Log return structure which contains raw stderr and stdout outputs. It is
+// safe to call concurrently with a running task.
+func (t *Task) Log() Log {
+	t.logMu.Lock()
+	defer t.logMu.Unlock()
+
 	return Log{
 		Stderr: t.log.Stderr,
 		Stdout: t.log.Stdout,
@@ -50,12 +142,22 @@ func (t Task) Log() Log {
 }
 
 // String return the actual exec cmd string of the task
-func (t Task) String() string {
+func (t *Task) String() string {
 	return t.rsync.cmd.String()
 }
 
-// Run starts rsync process with options
+// Run starts rsync process with options. It is a thin wrapper around
+// RunContext that discards progress events and never cancels.
 func (t *Task) Run() error {
+	return t.RunContext(context.Background(), nil)
+}
+
+// RunContext starts the rsync process with options and honors ctx
+// cancellation by killing the underlying rsync process. Every time a parsed
+// stdout line updates the task's State, a StateEvent is pushed to events (if
+// non-nil); a final StateEvent with Kind EventDone or EventError is pushed
+// once the process exits. RunContext does not close events.
+func (t *Task) RunContext(ctx context.Context, events chan<- StateEvent) error {
 	stderr, err := t.rsync.StderrPipe()
 	if err != nil {
 		return err
@@ -68,17 +170,60 @@ func (t *Task) Run() error {
 	}
 	defer stdout.Close()
 
+	start := time.Now()
+	if err := t.rsync.Start(); err != nil {
+		return err
+	}
+
+	killed := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			t.rsync.Kill()
+		case <-killed:
+		}
+	}()
+
 	var wg sync.WaitGroup
-	go processStdout(&wg, t, stdout)
-	go processStderr(&wg, t, stderr)
 	wg.Add(2)
+	go processStdout(ctx, &wg, t, stdout, events, start)
+	go processStderr(&wg, t, stderr)
 
-	err = t.rsync.Run()
 	wg.Wait()
+	err = t.rsync.Wait()
+	close(killed)
+
+	kind := EventDone
+	if err != nil {
+		kind = EventError
+	}
+	emit(ctx, events, t, kind)
+
+	// Prefer ctx.Err() over the raw process error whenever ctx was the
+	// reason the process stopped, so callers can tell a cancelled task
+	// (errors.Is(err, context.Canceled)) apart from one that actually
+	// failed on its own, regardless of which branch produced err.
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
 
 	return err
 }
 
+// emit pushes a StateEvent to events, unless ctx is done first. Without this
+// guard a consumer that stops reading events on ctx.Done() would leave
+// RunContext blocked forever on the final EventDone/EventError send, even
+// though the rsync process it's reporting on has already exited.
+func emit(ctx context.Context, events chan<- StateEvent, task *Task, kind EventKind) {
+	if events == nil {
+		return
+	}
+	select {
+	case events <- StateEvent{State: task.State(), Time: time.Now(), Kind: kind}:
+	case <-ctx.Done():
+	}
+}
+
 // NewTask returns new rsync task
 func NewTask(source, destination string, rsyncOptions RsyncOptions) *Task {
 	// Force set required options
@@ -90,6 +235,131 @@ func NewTask(source, destination string, rsyncOptions RsyncOptions) *Task {
 		rsync: NewRsync(source, destination, rsyncOptions),
 		state: &State{},
 		log:   &Log{},
+		files: make(chan FileEvent, fileEventBufferSize),
+	}
+}
+
+// Files returns the channel on which itemized file events are delivered
+// while RsyncOptions.ItemizeChanges is set. It is safe to call before or
+// during a run; the channel is never closed by Task.
+func (t *Task) Files() <-chan FileEvent {
+	return t.files
+}
+
+// RecentFiles returns up to n of the most recently itemized files, oldest
+// first. It is safe to call while the task is running.
+func (t *Task) RecentFiles(n int) []FileEvent {
+	t.filesMu.Lock()
+	defer t.filesMu.Unlock()
+
+	if n <= 0 || n > len(t.recentFiles) {
+		n = len(t.recentFiles)
+	}
+
+	files := make([]FileEvent, n)
+	copy(files, t.recentFiles[len(t.recentFiles)-n:])
+
+	return files
+}
+
+func (t *Task) addFileEvent(event FileEvent) {
+	t.filesMu.Lock()
+	t.recentFiles = append(t.recentFiles, event)
+	if len(t.recentFiles) > maxRecentFiles {
+		t.recentFiles = t.recentFiles[len(t.recentFiles)-maxRecentFiles:]
+	}
+	t.filesMu.Unlock()
+
+	select {
+	case t.files <- event:
+	default:
+	}
+}
+
+// itemizeEvent parses line as an itemized change if enabled is set. For
+// lines that carry the deterministic YXcstpoguax prefix rsync emits under
+// --itemize-changes, this takes precedence over the isFilename heuristic,
+// which mislabels many verbose lines; other lines still fall back to it.
+func itemizeEvent(enabled bool, line string) (FileEvent, bool) {
+	if !enabled {
+		return FileEvent{}, false
+	}
+	return parseItemizeEvent(line)
+}
+
+const deletingPrefix = "*deleting"
+
+func parseItemizeEvent(line string) (FileEvent, bool) {
+	if strings.HasPrefix(line, deletingPrefix) {
+		return FileEvent{
+			Op:   OpDeleted,
+			Path: strings.TrimSpace(strings.TrimPrefix(line, deletingPrefix)),
+		}, true
+	}
+
+	if len(line) < 13 || line[11] != ' ' || !isItemizePrefix(line[:11]) {
+		return FileEvent{}, false
+	}
+
+	prefix, rest := line[:11], line[12:]
+
+	event := FileEvent{Kind: fileKindFromByte(prefix[1])}
+	switch {
+	case prefix[2:] == "+++++++++":
+		event.Op = OpCreated
+	case prefix[0] == '<':
+		event.Op = OpSent
+	case prefix[0] == '>':
+		event.Op = OpReceived
+	default:
+		event.Op = OpChanged
+	}
+
+	if event.Kind == KindSymlink {
+		if path, target, ok := strings.Cut(rest, " -> "); ok {
+			event.Path, event.LinkTarget = path, target
+			return event, true
+		}
+	}
+	event.Path = rest
+
+	return event, true
+}
+
+// isItemizePrefix reports whether prefix looks like rsync's 11-character
+// YXcstpoguax itemize code, e.g. ">f+++++++++" or ".d..t......".
+func isItemizePrefix(prefix string) bool {
+	if len(prefix) != 11 {
+		return false
+	}
+	if !strings.ContainsRune("<>ch.*", rune(prefix[0])) {
+		return false
+	}
+	if !strings.ContainsRune("fdLDS", rune(prefix[1])) {
+		return false
+	}
+	for i := 2; i < len(prefix); i++ {
+		if !strings.ContainsRune("+.?csTtpoguax", rune(prefix[i])) {
+			return false
+		}
+	}
+	return true
+}
+
+func fileKindFromByte(b byte) FileKind {
+	switch b {
+	case 'f':
+		return KindFile
+	case 'd':
+		return KindDir
+	case 'L':
+		return KindSymlink
+	case 'D':
+		return KindDevice
+	case 'S':
+		return KindSpecial
+	default:
+		return KindUnknown
 	}
 }
 
@@ -116,7 +386,48 @@ func scanProgressLines(data []byte, atEOF bool) (advance int, token []byte, err
 	return 0, nil, nil
 }
 
-func processStdout(wg *sync.WaitGroup, task *Task, stdout io.Reader) {
+// jsonProgressEntry is one line of a Task's JSONProgress stream.
+type jsonProgressEntry struct {
+	Seq               uint64    `json:"seq"`
+	Time              time.Time `json:"time"`
+	Remain            int       `json:"remain"`
+	Total             int       `json:"total"`
+	Progress          float64   `json:"progress"`
+	Speed             string    `json:"speed"`
+	TransferedBytes   int64     `json:"transfered_bytes"`
+	TransferedPercent int       `json:"transfered_percent"`
+	Filename          string    `json:"filename"`
+}
+
+// jsonProgressSummary is the terminal object written to a Task's
+// JSONProgress stream once the underlying rsync process exits.
+type jsonProgressSummary struct {
+	Seq             uint64        `json:"seq"`
+	Time            time.Time     `json:"time"`
+	Summary         bool          `json:"summary"`
+	TransferedBytes int64         `json:"transfered_bytes"`
+	Elapsed         time.Duration `json:"elapsed"`
+	AverageSpeedBps float64       `json:"average_speed_bytes_per_sec"`
+}
+
+var (
+	sentReceivedMatcher = regexp.MustCompile(`^sent ([\d,]+) bytes\s+received ([\d,]+) bytes`)
+	totalSizeMatcher    = regexp.MustCompile(`total size is ([\d,]+)`)
+)
+
+func parseCommaInt(str string) int64 {
+	n, _ := strconv.ParseInt(strings.ReplaceAll(str, ",", ""), 10, 64)
+	return n
+}
+
+func writeJSONProgress(w io.Writer, v interface{}) {
+	if w == nil {
+		return
+	}
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func processStdout(ctx context.Context, wg *sync.WaitGroup, task *Task, stdout io.Reader, events chan<- StateEvent, start time.Time) {
 	const maxPercents = float64(100)
 	const minDivider = 1
 
@@ -126,6 +437,13 @@ func processStdout(wg *sync.WaitGroup, task *Task, stdout io.Reader) {
 	speedMatcher := newMatcher(`(\d+\.\d+.{2}\/s)`)
 	transferedMatcher := newMatcher(`(\S+.*)%`)
 
+	progress2 := task.rsync.hasArg("--info=progress2")
+	itemize := task.rsync.hasArg("--itemize-changes")
+
+	var seq uint64
+	var sentBytes, receivedBytes, totalSize int64
+	var smoothedSpeed, smoothedTotal float64
+
 	// Extract data from strings:
 	//         999,999 99%  999.99kB/s    0:00:59 (xfr#9, to-chk=999/9999)
 	//          2.39G  68%  659.73MB/s    0:00:03 (xfr#7217, to-chk=1113/10003)
@@ -133,27 +451,101 @@ func processStdout(wg *sync.WaitGroup, task *Task, stdout io.Reader) {
 	scanner.Split(scanProgressLines)
 	for scanner.Scan() {
 		logStr := scanner.Text()
+		kind := EventKind(-1)
+
+		event, hasEvent := itemizeEvent(itemize, logStr)
+
+		task.stateMu.Lock()
 		if progressMatcher.Match(logStr) {
 			task.state.Remain, task.state.Total = getTaskProgress(progressMatcher.Extract(logStr))
 
 			copiedCount := float64(task.state.Total - task.state.Remain)
 			task.state.Progress = copiedCount / math.Max(float64(task.state.Total), float64(minDivider)) * maxPercents
+			kind = EventProgress
 		}
 
 		if speedMatcher.Match(logStr) {
 			task.state.Speed = getTaskSpeed(speedMatcher.ExtractAllStringSubmatch(logStr, 2))
+			kind = EventProgress
 		}
 
 		if transferedMatcher.Match(logStr) {
 			task.state.TransferedBytes, task.state.TransferedPercent = getTaskTransfered(transferedMatcher.Extract(logStr))
+			kind = EventProgress
+
+			if progress2 {
+				task.state.TransferredTotalBytes = task.state.TransferedBytes
+				if task.state.TransferedPercent > 0 {
+					impliedTotal := float64(task.state.TransferredTotalBytes) * 100 / float64(task.state.TransferedPercent)
+					task.state.TotalBytes = smoothTotalBytes(&smoothedTotal, impliedTotal)
+				}
+				task.state.ElapsedSinceStart = time.Since(start)
+				task.state.ETA = estimateETA(&smoothedSpeed, task.state.Speed, task.state.TotalBytes-task.state.TransferredTotalBytes)
+			}
 		}
-		if isFilename(logStr) {
+		if hasEvent {
+			task.state.Filename = event.Path
+			kind = EventFile
+		} else if isFilename(logStr) {
 			task.state.Filename = logStr
+			kind = EventFile
+		}
+		task.stateMu.Unlock()
+
+		if hasEvent {
+			task.addFileEvent(event)
+		}
+
+		if m := sentReceivedMatcher.FindStringSubmatch(logStr); m != nil {
+			sentBytes, receivedBytes = parseCommaInt(m[1]), parseCommaInt(m[2])
+		}
+		if m := totalSizeMatcher.FindStringSubmatch(logStr); m != nil {
+			totalSize = parseCommaInt(m[1])
 		}
 
+		task.logMu.Lock()
 		task.log.Stdout += logStr + "\n"
+		task.logMu.Unlock()
+
+		if kind >= 0 {
+			emit(ctx, events, task, kind)
+
+			seq++
+			state := task.State()
+			writeJSONProgress(task.JSONProgress, jsonProgressEntry{
+				Seq:               seq,
+				Time:              time.Now(),
+				Remain:            state.Remain,
+				Total:             state.Total,
+				Progress:          state.Progress,
+				Speed:             state.Speed,
+				TransferedBytes:   state.TransferedBytes,
+				TransferedPercent: state.TransferedPercent,
+				Filename:          state.Filename,
+			})
+		}
+	}
+
+	transferedBytes := sentBytes + receivedBytes
+	if transferedBytes == 0 {
+		transferedBytes = totalSize
+	}
+
+	elapsed := time.Since(start)
+	var averageSpeed float64
+	if elapsed > 0 {
+		averageSpeed = float64(transferedBytes) / elapsed.Seconds()
 	}
 
+	seq++
+	writeJSONProgress(task.JSONProgress, jsonProgressSummary{
+		Seq:             seq,
+		Time:            time.Now(),
+		Summary:         true,
+		TransferedBytes: transferedBytes,
+		Elapsed:         elapsed,
+		AverageSpeedBps: averageSpeed,
+	})
 }
 
 func processStderr(wg *sync.WaitGroup, task *Task, stderr io.Reader) {
@@ -161,7 +553,9 @@ func processStderr(wg *sync.WaitGroup, task *Task, stderr io.Reader) {
 
 	scanner := bufio.NewScanner(stderr)
 	for scanner.Scan() {
+		task.logMu.Lock()
 		task.log.Stderr += scanner.Text() + "\n"
+		task.logMu.Unlock()
 	}
 }
 
@@ -236,6 +630,70 @@ func getTaskTransfered(transfered string) (transferedBytes int64, transferedPerc
 	return
 }
 
+// etaSmoothingFactor weighs newer speed samples against the running
+// estimate when computing ETA, similar to the EWMA smoothing restic and
+// butler use to keep progress renderers from oscillating between samples.
+const etaSmoothingFactor = 0.3
+
+// smoothTotalBytes folds impliedTotal - the total rsync implies by dividing
+// bytes transferred so far by its integer percent complete - into the EWMA
+// tracked by *smoothedTotal. Early in a transfer rsync's integer percent
+// swings the implied total wildly (1%->2% roughly halves it), which would
+// otherwise feed straight into estimateETA's remaining-bytes term and make
+// the ETA oscillate even though the speed sample itself is smoothed.
+func smoothTotalBytes(smoothedTotal *float64, impliedTotal float64) int64 {
+	if *smoothedTotal == 0 {
+		*smoothedTotal = impliedTotal
+	} else {
+		*smoothedTotal = etaSmoothingFactor*impliedTotal + (1-etaSmoothingFactor)**smoothedTotal
+	}
+
+	return int64(*smoothedTotal)
+}
+
+// estimateETA folds speed (as reported by rsync, e.g. "45.67MB/s") into the
+// EWMA tracked by *smoothedSpeed and returns the estimated time remaining
+// to transfer remainingBytes at that smoothed rate.
+func estimateETA(smoothedSpeed *float64, speed string, remainingBytes int64) time.Duration {
+	if remainingBytes <= 0 {
+		return 0
+	}
+
+	bytesPerSec := parseSpeedBytesPerSec(speed)
+	if bytesPerSec <= 0 {
+		return 0
+	}
+
+	if *smoothedSpeed == 0 {
+		*smoothedSpeed = bytesPerSec
+	} else {
+		*smoothedSpeed = etaSmoothingFactor*bytesPerSec + (1-etaSmoothingFactor)**smoothedSpeed
+	}
+
+	return time.Duration(float64(remainingBytes) / *smoothedSpeed * float64(time.Second))
+}
+
+func parseSpeedBytesPerSec(speed string) float64 {
+	speed = strings.TrimSuffix(speed, "/s")
+
+	units := []struct {
+		suffix     string
+		multiplier float64
+	}{
+		{"TB", 1 << 40}, {"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"kB", 1 << 10},
+		{"T", 1 << 40}, {"G", 1 << 30}, {"M", 1 << 20}, {"K", 1 << 10}, {"B", 1},
+	}
+
+	for _, u := range units {
+		if strings.HasSuffix(speed, u.suffix) {
+			number, _ := strconv.ParseFloat(strings.TrimSuffix(speed, u.suffix), 64)
+			return number * u.multiplier
+		}
+	}
+
+	return 0
+}
+
 // # Call this if you want to filter out verbose messages (-v or -vv) from
 // # the output of an rsync run (whittling the output down to just the file
 // # messages).  This isn't needed if you use -i without -v.
@@ -279,6 +737,7 @@ func isFilename(str string) bool {
 		"to consider",
 		"to-chk=",
 		"to-check=",
+		"ir-chk=",
 	}
 
 	for _, v := range verbose {
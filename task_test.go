@@ -1,9 +1,17 @@
 package grsync
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestTask(t *testing.T) {
@@ -15,6 +23,31 @@ func TestTask(t *testing.T) {
 	})
 }
 
+func TestScanProgressLinesCRRewrite(t *testing.T) {
+	// rsync repeatedly rewrites a --info=progress2 style line with bare
+	// \r, only settling on a final \n once the transfer is done.
+	data := []byte("1,000  10%   1.00MB/s    0:00:10 (xfr#1, to-chk=9/10)\r" +
+		"2,000  20%   1.00MB/s    0:00:09 (xfr#1, to-chk=8/10)\r" +
+		"3,000  30%   1.00MB/s    0:00:08 (xfr#1, to-chk=7/10)\n")
+
+	var tokens []string
+	for start := 0; start < len(data); {
+		advance, token, err := scanProgressLines(data[start:], false)
+		assert.NoError(t, err)
+		if advance == 0 {
+			break
+		}
+		tokens = append(tokens, string(token))
+		start += advance
+	}
+
+	assert.Equal(t, []string{
+		"1,000  10%   1.00MB/s    0:00:10 (xfr#1, to-chk=9/10)",
+		"2,000  20%   1.00MB/s    0:00:09 (xfr#1, to-chk=8/10)",
+		"3,000  30%   1.00MB/s    0:00:08 (xfr#1, to-chk=7/10)",
+	}, tokens)
+}
+
 func TestTaskProgressParse(t *testing.T) {
 	progressMatcher := newMatcher(`\(.+-chk=(\d+.\d+)`)
 	const taskInfoString = `999,999 99%  999.99kB/s    0:00:59 (xfr#9, to-chk=999/9999)`
@@ -66,3 +99,229 @@ func TestTaskTransfered(t *testing.T) {
 		assert.Equal(t, tt.expectPercent, percent)
 	}
 }
+
+func TestParseSpeedBytesPerSec(t *testing.T) {
+	tests := []struct {
+		speed    string
+		expected float64
+	}{
+		{"999.99kB/s", 999.99 * 1024},
+		{"45.67MB/s", 45.67 * 1024 * 1024},
+		{"1.20GB/s", 1.20 * 1024 * 1024 * 1024},
+	}
+
+	for _, tt := range tests {
+		assert.InDelta(t, tt.expected, parseSpeedBytesPerSec(tt.speed), 1)
+	}
+}
+
+func TestParseItemizeEvent(t *testing.T) {
+	tests := []struct {
+		line     string
+		expected FileEvent
+	}{
+		{
+			line:     ">f+++++++++ path/to/file",
+			expected: FileEvent{Op: OpCreated, Kind: KindFile, Path: "path/to/file"},
+		},
+		{
+			line:     ".d..t...... dir/",
+			expected: FileEvent{Op: OpChanged, Kind: KindDir, Path: "dir/"},
+		},
+		{
+			line:     "cL+++++++++ symlink -> target",
+			expected: FileEvent{Op: OpCreated, Kind: KindSymlink, Path: "symlink", LinkTarget: "target"},
+		},
+		{
+			line:     "*deleting   old/file",
+			expected: FileEvent{Op: OpDeleted, Path: "old/file"},
+		},
+	}
+
+	for _, tt := range tests {
+		event, ok := parseItemizeEvent(tt.line)
+		assert.True(t, ok, tt.line)
+		assert.Equal(t, tt.expected, event)
+	}
+}
+
+func TestEstimateETA(t *testing.T) {
+	var smoothed float64
+
+	// First sample seeds the EWMA directly.
+	eta := estimateETA(&smoothed, "1.00MB/s", 1024*1024*10)
+	assert.Equal(t, 10*time.Second, eta)
+
+	// A slower second sample pulls the smoothed speed down, so the ETA
+	// for the same remaining bytes should grow rather than jump straight
+	// to the new sample's ETA.
+	eta = estimateETA(&smoothed, "0.50MB/s", 1024*1024*10)
+	assert.Greater(t, eta, 10*time.Second)
+}
+
+func TestSmoothTotalBytes(t *testing.T) {
+	var smoothed float64
+
+	// First sample seeds the EWMA directly.
+	total := smoothTotalBytes(&smoothed, 1000)
+	assert.EqualValues(t, 1000, total)
+
+	// rsync's integer percent swings the implied total wildly early in a
+	// transfer (e.g. 1% -> 2% roughly halves it); the smoothed total
+	// should move toward the new sample rather than jump straight to it.
+	total = smoothTotalBytes(&smoothed, 500)
+	assert.Less(t, total, int64(1000))
+	assert.Greater(t, total, int64(500))
+}
+
+func TestIsFilename(t *testing.T) {
+	tests := []struct {
+		line     string
+		expected bool
+	}{
+		{"path/to/file", true},
+		{`999,999 99%  999.99kB/s    0:00:59 (xfr#9, to-chk=999/9999)`, false},
+		// rsync can report the remaining-files count as ir-chk= instead
+		// of to-chk=/to-check=; without excluding it here the raw
+		// progress line fell through and clobbered State.Filename.
+		{`2.39G  68%  659.73MB/s    0:00:03 (xfr#7217, ir-chk=1113/10003)`, false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, isFilename(tt.line), tt.line)
+	}
+}
+
+func TestTaskLogSafeDuringRun(t *testing.T) {
+	dir := t.TempDir()
+	script := "#!/bin/sh\n" +
+		"for i in $(seq 1 50); do echo \"line $i\"; echo \"err $i\" >&2; done\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "rsync"), []byte(script), 0o755))
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	task := NewTask("a", "b", RsyncOptions{})
+
+	// Poll Log concurrently with processStdout/processStderr writing to
+	// task.log, the same pattern State()/Pool.State() already guard
+	// against. Run under `go test -race` to catch an unguarded access.
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				task.Log()
+			}
+		}
+	}()
+
+	err := task.Run()
+	close(stop)
+	<-stopped
+
+	require.NoError(t, err)
+	assert.Contains(t, task.Log().Stdout, "line 50")
+	assert.Contains(t, task.Log().Stderr, "err 50")
+}
+
+func TestTaskJSONProgress(t *testing.T) {
+	dir := t.TempDir()
+	script := "#!/bin/sh\n" +
+		"echo '2.39G  68%  659.73MB/s    0:00:03 (xfr#7217, to-chk=1113/10003)'\n" +
+		"exit 0\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "rsync"), []byte(script), 0o755))
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	var buf bytes.Buffer
+	task := NewTask("a", "b", RsyncOptions{Progress: true})
+	task.JSONProgress = &buf
+
+	require.NoError(t, task.Run())
+
+	dec := json.NewDecoder(&buf)
+
+	var entry jsonProgressEntry
+	require.NoError(t, dec.Decode(&entry))
+	assert.Equal(t, uint64(1), entry.Seq)
+	assert.Equal(t, 1113, entry.Remain)
+	assert.Equal(t, 10003, entry.Total)
+	assert.Equal(t, "659.73MB/s", entry.Speed)
+
+	var summary jsonProgressSummary
+	require.NoError(t, dec.Decode(&summary))
+	assert.Equal(t, uint64(2), summary.Seq)
+	assert.True(t, summary.Summary)
+}
+
+func TestRunContextDeliversEvents(t *testing.T) {
+	withFakeRsync(t)
+
+	task := NewTask(t.TempDir()+"/marker", "ok", RsyncOptions{Progress: true})
+
+	events := make(chan StateEvent)
+	var kinds []EventKind
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for event := range events {
+			kinds = append(kinds, event.Kind)
+		}
+	}()
+
+	err := task.RunContext(context.Background(), events)
+	require.NoError(t, err)
+	close(events)
+	<-done
+
+	require.NotEmpty(t, kinds)
+	assert.Equal(t, EventDone, kinds[len(kinds)-1])
+}
+
+// TestRunContextCancellationDoesNotHang exercises a consumer that stops
+// reading events once ctx is done, and asserts RunContext still returns
+// promptly with ctx.Err() instead of blocking forever on its final emit.
+func TestRunContextCancellationDoesNotHang(t *testing.T) {
+	// exec replaces the shell with sleep so Task.rsync.Kill (a process
+	// signal) actually reaches the thing holding stdout/stderr open,
+	// instead of killing an intermediate shell while an orphaned sleep
+	// keeps the pipes alive for the remainder of its sleep.
+	dir := t.TempDir()
+	script := "#!/bin/sh\nexec sleep 5\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "rsync"), []byte(script), 0o755))
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	task := NewTask("a", "b", RsyncOptions{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events := make(chan StateEvent)
+
+	// Consumer reads events only until ctx is done, then stops, so nothing
+	// is left to receive RunContext's final event.
+	go func() {
+		for {
+			select {
+			case <-events:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- task.RunContext(ctx, events)
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-errCh:
+		assert.True(t, errors.Is(err, context.Canceled))
+	case <-time.After(2 * time.Second):
+		t.Fatal("RunContext did not return after ctx was cancelled and its consumer stopped reading events")
+	}
+}